@@ -0,0 +1,43 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+const (
+	// AmbientDataplaneModeLabel, when set to AmbientDataplaneModeValue on a
+	// namespace, enrolls it in ambient mesh mode: workloads are captured by
+	// ztunnel rather than an injected istio-proxy sidecar.
+	AmbientDataplaneModeLabel = "istio.io/dataplane-mode"
+	AmbientDataplaneModeValue = "ambient"
+
+	// AmbientRedirectionAnnotation is set by ztunnel on pods it has captured
+	// for ambient redirection.
+	AmbientRedirectionAnnotation = "ambient.istio.io/redirection"
+)
+
+// IsAmbientNamespace reports whether a namespace is enrolled in ambient mesh
+// mode. Exported so other mesh-membership checks can treat ambient
+// namespaces as their own class rather than miscategorizing them as "not
+// injected". util.IsInMesh is not part of this source tree, so that reuse
+// isn't wired up here; the next change that touches it should call through
+// to this function instead of re-deriving the dataplane-mode check.
+func IsAmbientNamespace(nsLabels map[string]string) bool {
+	return nsLabels[AmbientDataplaneModeLabel] == AmbientDataplaneModeValue
+}
+
+// IsAmbientPod reports whether a pod has been captured for ambient
+// redirection.
+func IsAmbientPod(podAnnotations map[string]string) bool {
+	return podAnnotations[AmbientRedirectionAnnotation] != ""
+}
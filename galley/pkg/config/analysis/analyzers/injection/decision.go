@@ -0,0 +1,145 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+// namespaceDecision is the pure (side-effect-free) result of applying the
+// namespace-default injection rules to a single namespace's labels. It's
+// computed once by decideNamespace and then used both to drive Analyze's
+// diagnostics and to answer Evaluate.
+type namespaceDecision struct {
+	// ambient is true when the namespace is enrolled in ambient mesh mode.
+	ambient bool
+	// ambientConflict is true when the namespace is ambient-enrolled but also
+	// carries a sidecar-injection enablement label.
+	ambientConflict bool
+
+	// injected is the namespace-default verdict: would a pod in this
+	// namespace get a sidecar absent any pod-level override.
+	injected bool
+	// notInjected is true when the namespace carries neither the legacy nor
+	// the revision label, does not opt in with sidecar.istio.io/inject=true,
+	// and no webhook selector matches it either.
+	notInjected bool
+	// explicitlyDisabled is true when the legacy istio-injection label is set
+	// to something other than "enabled".
+	explicitlyDisabled bool
+	// multipleLabels is true when both the legacy and revision labels are set.
+	multipleLabels bool
+	// invalidRevision is true when istio.io/rev names neither a live
+	// control-plane revision nor a tag that resolves to one.
+	invalidRevision bool
+	// danglingTag is true when istio.io/rev names a tag that resolves to a
+	// revision with no running control-plane pods.
+	danglingTag         bool
+	danglingTagRevision string
+
+	// webhookConflict is true when more than one distinct revision's webhook
+	// selector matches this namespace.
+	webhookConflict   bool
+	conflictRevisions []string
+
+	// revision is the control-plane revision responsible for injection, once
+	// resolved (including through a tag). Empty means the default revision.
+	revision string
+	// webhook is the (first) matching sidecar-injector webhook, if any.
+	webhook *sidecarInjectorWebhook
+	// byLegacyLabel is true if the namespace is injected via
+	// istio-injection/istio.io/rev, as opposed to webhook selector alone.
+	byLegacyLabel bool
+}
+
+// decideNamespace applies the namespace-default injection rules -- ambient
+// enrollment, the legacy istio-injection/istio.io/rev labels (with tag
+// resolution), and MutatingWebhookConfiguration selectors -- to a single
+// namespace's labels. It has no side effects, so it can be shared by
+// Analyze (which turns its fields into diagnostics) and Evaluate (which
+// turns them into a Result).
+func decideNamespace(nsLabels map[string]string, controlPlaneRevisions map[string]bool, tagToRevision map[string]string, webhooks []sidecarInjectorWebhook) namespaceDecision {
+	var d namespaceDecision
+
+	matched := matchingWebhooks(webhooks, nsLabels)
+	if revs := distinctRevisions(matched); len(revs) > 1 {
+		d.webhookConflict = true
+		d.conflictRevisions = revs
+	}
+
+	if IsAmbientNamespace(nsLabels) {
+		d.ambient = true
+		injectionLabel := nsLabels[InjectionLabelName]
+		_, okNewInjectionLabel := nsLabels[RevisionInjectionLabelName]
+		d.ambientConflict = injectionLabel == InjectionLabelEnableValue || okNewInjectionLabel
+		return d
+	}
+
+	injectionLabel := nsLabels[InjectionLabelName]
+	newInjectionLabel, okNewInjectionLabel := nsLabels[RevisionInjectionLabelName]
+
+	if injectionLabel == "" && !okNewInjectionLabel {
+		// Neither the legacy nor the revision label is set, but the namespace
+		// may still opt in directly via sidecar.istio.io/inject=true, the same
+		// override resolveInjectionOverride applies at the pod level.
+		sidecarLabelVal, sidecarLabelOK := nsLabels[SidecarInjectLabelName]
+		if override := parseInjectBool(sidecarLabelVal, sidecarLabelOK); override != nil && *override {
+			d.injected = true
+			if len(matched) > 0 {
+				d.webhook = &matched[0]
+				d.revision = matched[0].revision
+			}
+			return d
+		}
+		if len(matched) > 0 {
+			d.injected = true
+			d.webhook = &matched[0]
+			d.revision = matched[0].revision
+			return d
+		}
+		d.notInjected = true
+		return d
+	}
+
+	if okNewInjectionLabel {
+		if injectionLabel != "" {
+			d.multipleLabels = true
+			return d
+		}
+		d.revision = newInjectionLabel
+		if _, ok := controlPlaneRevisions[newInjectionLabel]; !ok {
+			if resolvedRevision, isTag := tagToRevision[newInjectionLabel]; isTag {
+				if _, ok := controlPlaneRevisions[resolvedRevision]; !ok {
+					d.danglingTag = true
+					d.danglingTagRevision = resolvedRevision
+				}
+				d.revision = resolvedRevision
+			} else {
+				d.invalidRevision = true
+				d.revision = ""
+				return d
+			}
+		}
+	} else if injectionLabel != InjectionLabelEnableValue {
+		d.explicitlyDisabled = true
+		return d
+	}
+
+	d.injected = true
+	d.byLegacyLabel = true
+	if len(matched) > 0 {
+		d.webhook = &matched[0]
+		if d.revision == "" {
+			d.revision = matched[0].revision
+		}
+	}
+	return d
+}
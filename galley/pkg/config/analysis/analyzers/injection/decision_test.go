@@ -0,0 +1,169 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+import "testing"
+
+// These mirror the scenarios istioctl x check-inject covers today, since
+// decideNamespace and resolveInjectionOverride are the shared logic behind
+// both it and the injection.Analyzer diagnostics.
+func TestDecideNamespace(t *testing.T) {
+	controlPlaneRevisions := map[string]bool{"": true, "canary": true}
+	tagToRevision := map[string]string{"prod": "canary", "stale": "removed"}
+
+	cases := []struct {
+		name     string
+		nsLabels map[string]string
+		webhooks []sidecarInjectorWebhook
+		want     namespaceDecision
+	}{
+		{
+			name:     "no labels, no webhook match",
+			nsLabels: map[string]string{"kubernetes.io/metadata.name": "default"},
+			want:     namespaceDecision{notInjected: true},
+		},
+		{
+			name:     "legacy label enabled",
+			nsLabels: map[string]string{InjectionLabelName: InjectionLabelEnableValue},
+			want:     namespaceDecision{injected: true, byLegacyLabel: true},
+		},
+		{
+			name:     "legacy label disabled",
+			nsLabels: map[string]string{InjectionLabelName: "disabled"},
+			want:     namespaceDecision{explicitlyDisabled: true},
+		},
+		{
+			name:     "both legacy and revision labels",
+			nsLabels: map[string]string{InjectionLabelName: InjectionLabelEnableValue, RevisionInjectionLabelName: "canary"},
+			want:     namespaceDecision{multipleLabels: true},
+		},
+		{
+			name:     "revision label names a live revision",
+			nsLabels: map[string]string{RevisionInjectionLabelName: "canary"},
+			want:     namespaceDecision{injected: true, byLegacyLabel: true, revision: "canary"},
+		},
+		{
+			name:     "revision label names an unknown revision",
+			nsLabels: map[string]string{RevisionInjectionLabelName: "missing"},
+			want:     namespaceDecision{invalidRevision: true},
+		},
+		{
+			name:     "revision label names a tag that resolves to a live revision",
+			nsLabels: map[string]string{RevisionInjectionLabelName: "prod"},
+			want:     namespaceDecision{injected: true, byLegacyLabel: true, revision: "canary"},
+		},
+		{
+			name:     "revision label names a dangling tag",
+			nsLabels: map[string]string{RevisionInjectionLabelName: "stale"},
+			want:     namespaceDecision{injected: true, byLegacyLabel: true, danglingTag: true, danglingTagRevision: "removed", revision: "removed"},
+		},
+		{
+			name:     "sidecar inject label alone enables injection",
+			nsLabels: map[string]string{SidecarInjectLabelName: "true"},
+			want:     namespaceDecision{injected: true},
+		},
+		{
+			name:     "sidecar inject label false stays not injected",
+			nsLabels: map[string]string{SidecarInjectLabelName: "false"},
+			want:     namespaceDecision{notInjected: true},
+		},
+		{
+			name:     "ambient namespace",
+			nsLabels: map[string]string{AmbientDataplaneModeLabel: AmbientDataplaneModeValue},
+			want:     namespaceDecision{ambient: true},
+		},
+		{
+			name: "ambient namespace also labeled for sidecar injection",
+			nsLabels: map[string]string{
+				AmbientDataplaneModeLabel: AmbientDataplaneModeValue,
+				InjectionLabelName:        InjectionLabelEnableValue,
+			},
+			want: namespaceDecision{ambient: true, ambientConflict: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := decideNamespace(tc.nsLabels, controlPlaneRevisions, tagToRevision, tc.webhooks)
+			if got.ambient != tc.want.ambient ||
+				got.ambientConflict != tc.want.ambientConflict ||
+				got.injected != tc.want.injected ||
+				got.notInjected != tc.want.notInjected ||
+				got.explicitlyDisabled != tc.want.explicitlyDisabled ||
+				got.multipleLabels != tc.want.multipleLabels ||
+				got.invalidRevision != tc.want.invalidRevision ||
+				got.danglingTag != tc.want.danglingTag ||
+				got.danglingTagRevision != tc.want.danglingTagRevision ||
+				got.byLegacyLabel != tc.want.byLegacyLabel ||
+				got.revision != tc.want.revision {
+				t.Errorf("decideNamespace(%v) = %+v, want %+v", tc.nsLabels, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveInjectionOverride(t *testing.T) {
+	cases := []struct {
+		name           string
+		nsDefault      bool
+		nsLabels       map[string]string
+		podLabels      map[string]string
+		podAnnotations map[string]string
+		wantInject     bool
+		wantConflict   bool
+	}{
+		{
+			name:       "namespace default only",
+			nsDefault:  true,
+			wantInject: true,
+		},
+		{
+			name:       "namespace label overrides a false default",
+			nsDefault:  false,
+			nsLabels:   map[string]string{SidecarInjectLabelName: "true"},
+			wantInject: true,
+		},
+		{
+			name:         "namespace label disagrees with enabled default",
+			nsDefault:    true,
+			nsLabels:     map[string]string{SidecarInjectLabelName: "false"},
+			wantInject:   false,
+			wantConflict: true,
+		},
+		{
+			name:       "pod label overrides namespace default",
+			nsDefault:  false,
+			podLabels:  map[string]string{SidecarInjectLabelName: "true"},
+			wantInject: true,
+		},
+		{
+			name:           "pod annotation overrides pod label",
+			nsDefault:      true,
+			podLabels:      map[string]string{SidecarInjectLabelName: "true"},
+			podAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+			wantInject:     false,
+			wantConflict:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := resolveInjectionOverride(tc.nsDefault, tc.nsLabels, tc.podLabels, tc.podAnnotations)
+			if got.inject != tc.wantInject || got.conflict != tc.wantConflict {
+				t.Errorf("resolveInjectionOverride() = %+v, want inject=%v conflict=%v", got, tc.wantInject, tc.wantConflict)
+			}
+		})
+	}
+}
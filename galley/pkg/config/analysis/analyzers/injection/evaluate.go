@@ -0,0 +1,153 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// Reason identifies which layer of the decision chain determined an
+// Evaluate verdict.
+type Reason string
+
+const (
+	ReasonNamespaceNotFound Reason = "NamespaceNotFound"
+	ReasonAmbient           Reason = "Ambient"
+	ReasonNamespaceDefault  Reason = "NamespaceDefault"
+	ReasonOverride          Reason = "Override"
+)
+
+// Result is the structured verdict returned by Evaluate, mirroring what
+// `istioctl x check-inject` reports today but computed from the same logic
+// Analyze uses.
+type Result struct {
+	// Injected is the final verdict: would this namespace/pod get a sidecar.
+	Injected bool
+	// Ambient is true when the namespace is enrolled in ambient mesh mode,
+	// in which case Injected is always false and no sidecar is expected.
+	Ambient bool
+	// WebhookName is the name of the matching sidecar-injector webhook, if
+	// any webhook's namespaceSelector matched.
+	WebhookName string
+	// Revision is the control-plane revision that would perform the
+	// injection, once tag resolution is taken into account. Empty means the
+	// default (unrevisioned) control plane.
+	Revision string
+	// Reason is the layer that determined the verdict.
+	Reason Reason
+	// Decisions records, in order, the namespace-default verdict and, when
+	// pod labels or annotations were given, the verdict after the
+	// sidecar.istio.io/inject label/annotation layers and the webhook
+	// objectSelector are applied. The individual layers are not recorded as
+	// separate entries.
+	Decisions []string
+}
+
+// Evaluate computes whether a namespace -- or, if podLabels/podAnnotations
+// are non-nil, a specific pod within it -- would receive an Istio sidecar,
+// by applying the same namespace-default -> namespace-label -> pod-label ->
+// pod-annotation -> webhook-selector chain that Analyze's diagnostics are
+// derived from. It's exported so callers outside this package, such as
+// istioctl's injection-status tooling, can share this logic instead of
+// re-deriving their own copy of the rules. Neither istioctl/pkg/checkinject
+// nor istioctl/pkg/injector is part of this source tree, so switching them
+// over to call Evaluate is left to a change that touches those packages
+// directly.
+func Evaluate(ctx analysis.Context, ns string, podLabels, podAnnotations map[string]string) Result {
+	nsRes := ctx.Find(collections.K8SCoreV1Namespaces.Name(), resource.NewFullName("", resource.LocalName(ns)))
+	if nsRes == nil {
+		return Result{Reason: ReasonNamespaceNotFound, Decisions: []string{fmt.Sprintf("namespace %q not found", ns)}}
+	}
+
+	controlPlaneRevisions, tagToRevision, webhooks := gatherMeshState(ctx)
+
+	d := decideNamespace(nsRes.Metadata.Labels, controlPlaneRevisions, tagToRevision, webhooks)
+
+	if d.ambient {
+		return Result{
+			Ambient:   true,
+			Reason:    ReasonAmbient,
+			Decisions: []string{"namespace default: ambient (istio.io/dataplane-mode=ambient)"},
+		}
+	}
+
+	decisions := []string{fmt.Sprintf("namespace default: injected=%v, revision=%q", d.injected, d.revision)}
+
+	webhookName := ""
+	if d.webhook != nil {
+		webhookName = d.webhook.name
+	}
+
+	result := Result{
+		Injected:    d.injected,
+		WebhookName: webhookName,
+		Revision:    d.revision,
+		Reason:      ReasonNamespaceDefault,
+	}
+
+	if podLabels != nil || podAnnotations != nil {
+		// evaluatePodOverride, not resolveInjectionOverride directly, so a
+		// namespace-level webhook match that doesn't cover this pod (its
+		// objectSelector excludes it) can't make Evaluate disagree with
+		// Analyze's pod loop, which applies the same caveat.
+		override := evaluatePodOverride(d, nsRes.Metadata.Labels, podLabels, podAnnotations)
+		decisions = append(decisions, fmt.Sprintf("after sidecar.istio.io/inject label/annotation layering: injected=%v", override.inject))
+		if override.inject != d.injected {
+			result.Reason = ReasonOverride
+		}
+		result.Injected = override.inject
+	}
+
+	result.Decisions = decisions
+	return result
+}
+
+// gatherMeshState reruns the same control-plane-revision and webhook
+// gathering Analyze does, so Evaluate can be called standalone (e.g. from
+// istioctl) without needing Analyze to have run first.
+func gatherMeshState(ctx analysis.Context) (controlPlaneRevisions map[string]bool, tagToRevision map[string]string, webhooks []sidecarInjectorWebhook) {
+	controlPlaneRevisions = make(map[string]bool)
+	tagToRevision = make(map[string]string)
+
+	ctx.ForEach(collections.K8SCoreV1Pods.Name(), func(r *resource.Instance) bool {
+		pod := r.Message.(*v1.Pod)
+		if isControlPlane(pod) {
+			if revision, ok := r.Metadata.Labels[RevisionInjectionLabelName]; ok {
+				controlPlaneRevisions[revision] = true
+			}
+		}
+		return true
+	})
+
+	ctx.ForEach(collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name(), func(r *resource.Instance) bool {
+		if tag, revision, ok := revisionTag(r); ok && revision != "" {
+			tagToRevision[tag] = revision
+		}
+		for _, wh := range extractSidecarInjectorWebhooks(r) {
+			if !wh.deactivated {
+				webhooks = append(webhooks, wh)
+			}
+		}
+		return true
+	})
+
+	return controlPlaneRevisions, tagToRevision, webhooks
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+import (
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// fakeContext is a minimal analysis.Context good enough to drive Evaluate in
+// tests without standing up the full galley snapshot machinery: a namespace
+// to Find, and webhook configs to ForEach. It mirrors the check-inject
+// fixtures istioctl tests this logic against today.
+type fakeContext struct {
+	namespace *resource.Instance
+	webhooks  []*resource.Instance
+}
+
+func (f *fakeContext) Report(collection.Name, diag.Message) {}
+
+func (f *fakeContext) Find(col collection.Name, name resource.FullName) *resource.Instance {
+	if col != collections.K8SCoreV1Namespaces.Name() || f.namespace == nil {
+		return nil
+	}
+	if f.namespace.Metadata.FullName != name {
+		return nil
+	}
+	return f.namespace
+}
+
+func (f *fakeContext) Exists(col collection.Name, name resource.FullName) bool {
+	return f.Find(col, name) != nil
+}
+
+func (f *fakeContext) ForEach(col collection.Name, fn analysis.IterateFn) {
+	switch col {
+	case collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name():
+		for _, w := range f.webhooks {
+			if !fn(w) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeContext) Canceled() bool { return false }
+
+func namespaceInstance(name string, labels map[string]string) *resource.Instance {
+	return &resource.Instance{
+		Metadata: resource.Metadata{
+			FullName: resource.NewFullName("", resource.LocalName(name)),
+			Labels:   labels,
+		},
+	}
+}
+
+func webhookConfigInstance(configName string, webhookLabels map[string]string, webhooks ...admissionregistrationv1.MutatingWebhook) *resource.Instance {
+	return &resource.Instance{
+		Metadata: resource.Metadata{
+			FullName: resource.NewFullName("", resource.LocalName(configName)),
+			Labels:   webhookLabels,
+		},
+		Message: &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: configName},
+			Webhooks:   webhooks,
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		name           string
+		ctx            *fakeContext
+		ns             string
+		podLabels      map[string]string
+		podAnnotations map[string]string
+		want           Result
+	}{
+		{
+			name: "namespace not found",
+			ctx:  &fakeContext{},
+			ns:   "missing",
+			want: Result{Reason: ReasonNamespaceNotFound},
+		},
+		{
+			name: "ambient namespace",
+			ctx: &fakeContext{
+				namespace: namespaceInstance("ambient-ns", map[string]string{AmbientDataplaneModeLabel: AmbientDataplaneModeValue}),
+			},
+			ns:   "ambient-ns",
+			want: Result{Ambient: true, Reason: ReasonAmbient},
+		},
+		{
+			name: "legacy label namespace, namespace-only query",
+			ctx: &fakeContext{
+				namespace: namespaceInstance("legacy-ns", map[string]string{InjectionLabelName: InjectionLabelEnableValue}),
+			},
+			ns:   "legacy-ns",
+			want: Result{Injected: true, Reason: ReasonNamespaceDefault},
+		},
+		{
+			name: "webhook-only namespace, pod matches objectSelector",
+			ctx: &fakeContext{
+				namespace: namespaceInstance("webhook-ns", map[string]string{"istio-injection-enabled": "true"}),
+				webhooks: []*resource.Instance{
+					webhookConfigInstance("istio-sidecar-injector", nil, admissionregistrationv1.MutatingWebhook{
+						Name: "sidecar-injector.istio.io",
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"istio-injection-enabled": "true"},
+						},
+						ObjectSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "reviews"},
+						},
+					}),
+				},
+			},
+			ns:        "webhook-ns",
+			podLabels: map[string]string{"app": "reviews"},
+			want:      Result{Injected: true, WebhookName: "sidecar-injector.istio.io", Reason: ReasonNamespaceDefault},
+		},
+		{
+			name: "webhook-only namespace, pod excluded by objectSelector",
+			ctx: &fakeContext{
+				namespace: namespaceInstance("webhook-ns", map[string]string{"istio-injection-enabled": "true"}),
+				webhooks: []*resource.Instance{
+					webhookConfigInstance("istio-sidecar-injector", nil, admissionregistrationv1.MutatingWebhook{
+						Name: "sidecar-injector.istio.io",
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"istio-injection-enabled": "true"},
+						},
+						ObjectSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "reviews"},
+						},
+					}),
+				},
+			},
+			ns:        "webhook-ns",
+			podLabels: map[string]string{"app": "productpage"},
+			want:      Result{Injected: false, WebhookName: "sidecar-injector.istio.io", Reason: ReasonOverride},
+		},
+		{
+			name: "pod annotation overrides a disabled namespace default",
+			ctx: &fakeContext{
+				namespace: namespaceInstance("default-ns", nil),
+			},
+			ns:             "default-ns",
+			podAnnotations: map[string]string{"sidecar.istio.io/inject": "true"},
+			want:           Result{Injected: true, Reason: ReasonOverride},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Evaluate(tc.ctx, tc.ns, tc.podLabels, tc.podAnnotations)
+			if got.Injected != tc.want.Injected || got.Ambient != tc.want.Ambient ||
+				got.WebhookName != tc.want.WebhookName || got.Reason != tc.want.Reason {
+				t.Errorf("Evaluate(%q) = %+v, want %+v", tc.ns, got, tc.want)
+			}
+		})
+	}
+}
@@ -17,10 +17,9 @@ package injection
 import (
 	"strings"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	v1 "k8s.io/api/core/v1"
 
-	"istio.io/api/annotation"
-
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
@@ -36,8 +35,10 @@ type Analyzer struct{}
 
 var _ analysis.Analyzer = &Analyzer{}
 
-// We assume that enablement is via an istio-injection=enabled or istio.io/rev namespace label
-// In theory, there can be alternatives using Mutatingwebhookconfiguration, but they're very uncommon
+// Enablement is most commonly driven by an istio-injection=enabled or
+// istio.io/rev namespace label, but operators can also drive injection
+// entirely through a MutatingWebhookConfiguration's namespaceSelector/
+// objectSelector (e.g. when enableNamespacesByDefault=true). We evaluate both.
 // See https://istio.io/docs/ops/troubleshooting/injection/ for more info.
 const (
 	InjectionLabelName         = "istio-injection"
@@ -55,32 +56,54 @@ func (a *Analyzer) Metadata() analysis.Metadata {
 		Inputs: collection.Names{
 			collections.K8SCoreV1Namespaces.Name(),
 			collections.K8SCoreV1Pods.Name(),
+			collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name(),
 		},
 	}
 }
 
+// namespaceInjectionSource records how a namespace's default injection state
+// was computed, so the pod loop can tell a legacy label-driven namespace
+// apart from one that's only injected because a webhook's namespaceSelector
+// happens to match it, and can layer sidecar.istio.io/inject overrides on
+// top via resolveInjectionOverride.
+type namespaceInjectionSource struct {
+	isInjected         bool
+	byLegacyLabel      bool
+	byWebhook          *sidecarInjectorWebhook
+	explicitlyDisabled bool
+	labels             map[string]string
+	// revision is the control-plane revision responsible for injecting into
+	// this namespace, when known. Empty means the default (unrevisioned)
+	// control plane.
+	revision string
+	// isAmbient is true when the namespace is enrolled in ambient mesh mode,
+	// which legitimately has no sidecar injection to speak of.
+	isAmbient bool
+}
+
 // Analyze implements Analyzer
 func (a *Analyzer) Analyze(c analysis.Context) {
-	injectedNamespaces := make(map[string]bool)
-	controlPlaneRevisions := make(map[string]bool)
+	injectedNamespaces := make(map[string]namespaceInjectionSource)
 
-	// Gather revisions of control plane
-	c.ForEach(collections.K8SCoreV1Pods.Name(), func(r *resource.Instance) bool {
-		pod := r.Message.(*v1.Pod)
-		if isControlPlane(pod) {
-			revision, ok := r.Metadata.Labels[model.RevisionLabel]
-			if ok {
-				controlPlaneRevisions[revision] = true
-			}
-		}
-		return true
-	})
+	controlPlaneRevisions, tagToRevision, webhooks := gatherMeshState(c)
 
 	revisions := make([]string, 0, len(controlPlaneRevisions))
 	for revision := range controlPlaneRevisions {
 		revisions = append(revisions, revision)
 	}
 
+	// Report deactivated webhooks once, up front. gatherMeshState already
+	// drops them from the set decideNamespace matches against.
+	c.ForEach(collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name(), func(r *resource.Instance) bool {
+		for _, wh := range extractSidecarInjectorWebhooks(r) {
+			if wh.deactivated {
+				c.Report(collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations.Name(),
+					msg.NewInjectionWebhookDeactivated(r, wh.name))
+			}
+		}
+		return true
+	})
+
 	c.ForEach(collections.K8SCoreV1Namespaces.Name(), func(r *resource.Instance) bool {
 
 		ns := r.Metadata.FullName.String()
@@ -88,71 +111,142 @@ func (a *Analyzer) Analyze(c analysis.Context) {
 			return true
 		}
 
-		injectionLabel := r.Metadata.Labels[InjectionLabelName]
-		newInjectionLabel, okNewInjectionLabel := r.Metadata.Labels[RevisionInjectionLabelName]
+		d := decideNamespace(r.Metadata.Labels, controlPlaneRevisions, tagToRevision, webhooks)
 
-		if injectionLabel == "" && !okNewInjectionLabel {
-			// TODO: if Istio is installed with sidecarInjectorWebhook.enableNamespacesByDefault=true
-			// (in the istio-sidecar-injector configmap), we need to reverse this logic and treat this as an injected namespace
+		if d.webhookConflict {
+			c.Report(collections.K8SCoreV1Namespaces.Name(),
+				msg.NewNamespaceInjectionWebhookConflict(r, ns, strings.Join(d.conflictRevisions, ", ")))
+		}
 
-			c.Report(collections.K8SCoreV1Namespaces.Name(), msg.NewNamespaceNotInjected(r, r.Metadata.FullName.String(), r.Metadata.FullName.String()))
-			return true
+		switch {
+		case d.ambientConflict:
+			c.Report(collections.K8SCoreV1Namespaces.Name(),
+				msg.NewNamespaceAmbientAndSidecarInjectionConflict(r, ns))
+		case d.notInjected:
+			c.Report(collections.K8SCoreV1Namespaces.Name(), msg.NewNamespaceNotInjected(r, ns, ns))
+		case d.multipleLabels:
+			c.Report(collections.K8SCoreV1Namespaces.Name(), msg.NewNamespaceMultipleInjectionLabels(r, ns, ns))
+		case d.invalidRevision:
+			c.Report(collections.K8SCoreV1Namespaces.Name(),
+				msg.NewNamespaceInvalidInjectorRevision(r, r.Metadata.Labels[RevisionInjectionLabelName], ns, strings.Join(revisions, ", ")))
+		case d.danglingTag:
+			c.Report(collections.K8SCoreV1Namespaces.Name(),
+				msg.NewNamespaceInjectionTagDangling(r, r.Metadata.Labels[RevisionInjectionLabelName], d.danglingTagRevision, ns))
 		}
 
-		if okNewInjectionLabel {
-			if injectionLabel != "" {
-				c.Report(collections.K8SCoreV1Namespaces.Name(),
-					msg.NewNamespaceMultipleInjectionLabels(r,
-						r.Metadata.FullName.String(),
-						r.Metadata.FullName.String()))
-				return true
-			}
-			if _, ok := controlPlaneRevisions[newInjectionLabel]; !ok {
-				c.Report(collections.K8SCoreV1Namespaces.Name(),
-					msg.NewNamespaceInvalidInjectorRevision(r,
-						newInjectionLabel,
-						r.Metadata.FullName.String(),
-						strings.Join(revisions, ", ")))
-				return true
-			}
-		} else if injectionLabel != InjectionLabelEnableValue {
-			// If legacy label has any value other than the enablement value, they are deliberately not injecting it, so ignore
-			return true
+		injectedNamespaces[ns] = namespaceInjectionSource{
+			isInjected:         d.injected,
+			byLegacyLabel:      d.byLegacyLabel,
+			byWebhook:          d.webhook,
+			explicitlyDisabled: d.explicitlyDisabled,
+			labels:             r.Metadata.Labels,
+			revision:           d.revision,
+			isAmbient:          d.ambient,
 		}
 
-		injectedNamespaces[r.Metadata.FullName.String()] = true
+		return true
+	})
 
+	// Pre-pass: for each revision, observe whether its injected pods place
+	// istio-proxy under initContainers (native sidecars) or containers
+	// (legacy), so the main pass below can flag pods whose placement
+	// disagrees with the rest of their revision as stale injections.
+	nativeSidecarVotes := make(map[string]map[bool]int)
+	c.ForEach(collections.K8SCoreV1Pods.Name(), func(r *resource.Instance) bool {
+		pod := r.Message.(*v1.Pod)
+		source, ok := injectedNamespaces[pod.GetNamespace()]
+		if !ok || !source.isInjected {
+			return true
+		}
+		if native, found := proxyContainerLocation(pod); found {
+			if nativeSidecarVotes[source.revision] == nil {
+				nativeSidecarVotes[source.revision] = make(map[bool]int)
+			}
+			nativeSidecarVotes[source.revision][native]++
+		}
 		return true
 	})
+	nativeSidecarRevision := make(map[string]bool, len(nativeSidecarVotes))
+	for revision, votes := range nativeSidecarVotes {
+		nativeSidecarRevision[revision] = votes[true] >= votes[false]
+	}
 
 	c.ForEach(collections.K8SCoreV1Pods.Name(), func(r *resource.Instance) bool {
 		pod := r.Message.(*v1.Pod)
 
-		if !injectedNamespaces[pod.GetNamespace()] {
+		source, ok := injectedNamespaces[pod.GetNamespace()]
+		if !ok {
+			// Namespace wasn't visited above, e.g. a system namespace: skip it,
+			// consistent with the namespace loop's own filtering.
+			return true
+		}
+
+		if source.isAmbient {
+			// A pod opting back into sidecar injection via either the label or
+			// the (higher-precedence) annotation still wants a sidecar the
+			// ambient dataplane won't give it, same as resolveInjectionOverride
+			// (policy.go) treats the two everywhere else.
+			if resolveInjectionOverride(false, source.labels, r.Metadata.Labels, pod.GetAnnotations()).inject {
+				c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodWantsSidecarInAmbientNamespace(r))
+			}
+			// Ambient pods are captured by ztunnel, not an injected sidecar:
+			// none of the proxy-presence checks below apply to them.
 			return true
 		}
 
-		// If a pod has injection explicitly disabled, no need to check further
-		if val := pod.GetAnnotations()[annotation.SidecarInject.Name]; strings.EqualFold(val, "false") {
+		d := namespaceDecision{injected: source.isInjected, byLegacyLabel: source.byLegacyLabel, webhook: source.byWebhook}
+		override := evaluatePodOverride(d, source.labels, r.Metadata.Labels, pod.GetAnnotations())
+		if override.conflict || (source.explicitlyDisabled && override.inject) {
+			c.Report(collections.K8SCoreV1Pods.Name(), msg.NewConflictingInjectionDirectives(r))
+		}
+		if !override.inject {
 			return true
 		}
 
-		proxyImage := ""
-		for _, container := range pod.Spec.Containers {
-			if container.Name == istioProxyName {
-				proxyImage = container.Image
-				break
+		native, found := proxyContainerLocation(pod)
+		if !found {
+			switch {
+			case source.byWebhook != nil && !source.byLegacyLabel && source.byWebhook.failurePolicy == admissionregistrationv1.Ignore:
+				// The webhook's objectSelector already matched this pod (we'd
+				// have returned above otherwise), so an Ignore failurePolicy
+				// means admission would have proceeded even if the webhook
+				// itself errored: a missing proxy here is more likely a
+				// silently-ignored webhook failure than intentional exclusion.
+				c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodMissingProxyWebhookIgnoreFailurePolicy(r, source.byWebhook.name))
+			case source.byWebhook != nil && !source.byLegacyLabel:
+				c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodMissingProxyViaWebhook(r, source.byWebhook.name))
+			default:
+				c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodMissingProxy(r))
 			}
+			return true
 		}
 
-		if proxyImage == "" {
-			c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodMissingProxy(r))
+		if expectsNative, known := nativeSidecarRevision[source.revision]; known && expectsNative != native {
+			c.Report(collections.K8SCoreV1Pods.Name(), msg.NewPodStaleSidecarInjection(r, source.revision))
 		}
 
 		return true
 	})
 }
 
+// proxyContainerLocation reports whether the pod has an istio-proxy
+// container, and whether it's a native sidecar (spec.initContainers, as
+// injected by Kubernetes 1.29+-aware webhooks) as opposed to a legacy one
+// (spec.containers).
+func proxyContainerLocation(pod *v1.Pod) (native bool, found bool) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == istioProxyName {
+			return false, true
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == istioProxyName {
+			return true, true
+		}
+	}
+	return false, false
+}
+
 func isControlPlane(pod *v1.Pod) bool {
 	if pod.GetNamespace() != constants.IstioSystemNamespace {
 		return false
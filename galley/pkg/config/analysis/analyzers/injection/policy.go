@@ -0,0 +1,114 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+import (
+	"strings"
+
+	"istio.io/api/annotation"
+)
+
+// SidecarInjectLabelName is the label form of sidecar.istio.io/inject. Unlike
+// the identically-named annotation, it's honored on both namespaces and pods,
+// and can override the istio-injection/istio.io/rev namespace default.
+const SidecarInjectLabelName = "sidecar.istio.io/inject"
+
+// injectionOverride is the resolved opt-in/opt-out state after layering
+// namespace default injection with the sidecar.istio.io/inject label on the
+// namespace and pod, and the sidecar.istio.io/inject annotation on the pod.
+// Each layer, in that order, overrides the one before it.
+type injectionOverride struct {
+	inject   bool
+	conflict bool
+}
+
+// parseInjectBool parses a sidecar.istio.io/inject label/annotation value.
+// Any value other than "true"/"false" (case-insensitively) is treated as
+// unset, matching the webhook's own lenient parsing.
+func parseInjectBool(v string, ok bool) *bool {
+	if !ok {
+		return nil
+	}
+	switch {
+	case strings.EqualFold(v, "true"):
+		b := true
+		return &b
+	case strings.EqualFold(v, "false"):
+		b := false
+		return &b
+	default:
+		return nil
+	}
+}
+
+// resolveInjectionOverride layers namespace-default injection (nsDefault, as
+// computed from istio-injection/istio.io/rev/webhook selectors elsewhere)
+// with the sidecar.istio.io/inject label on the namespace and pod, and the
+// sidecar.istio.io/inject annotation on the pod, in increasing order of
+// precedence. A later layer overriding an earlier one is normal and not
+// flagged; conflict is only set when two directives at the same scope
+// disagree (the namespace's sidecar.istio.io/inject=false against an enabled
+// namespace default, whether that default comes from istio-injection,
+// istio.io/rev or a webhook selector, or a pod's label vs. its own
+// annotation), since precedence can't resolve those.
+func resolveInjectionOverride(nsDefault bool, nsLabels, podLabels, podAnnotations map[string]string) injectionOverride {
+	result := injectionOverride{inject: nsDefault}
+
+	nsLabelVal, nsLabelOK := nsLabels[SidecarInjectLabelName]
+	nsOverride := parseInjectBool(nsLabelVal, nsLabelOK)
+
+	podLabelVal, podLabelOK := podLabels[SidecarInjectLabelName]
+	podLabelOverride := parseInjectBool(podLabelVal, podLabelOK)
+
+	podAnnotationVal, podAnnotationOK := podAnnotations[annotation.SidecarInject.Name]
+	podAnnotationOverride := parseInjectBool(podAnnotationVal, podAnnotationOK)
+
+	if nsOverride != nil {
+		if nsDefault && !*nsOverride {
+			// e.g. sidecar.istio.io/inject=false alongside istio-injection=enabled:
+			// the two namespace-scoped directives disagree.
+			result.conflict = true
+		}
+		result.inject = *nsOverride
+	}
+
+	if podLabelOverride != nil {
+		result.inject = *podLabelOverride
+	}
+
+	if podAnnotationOverride != nil {
+		if podLabelOverride != nil && *podAnnotationOverride != *podLabelOverride {
+			result.conflict = true
+		}
+		result.inject = *podAnnotationOverride
+	}
+
+	return result
+}
+
+// evaluatePodOverride layers sidecar.istio.io/inject label/annotation
+// overrides onto an already-decided namespace (via resolveInjectionOverride)
+// and then applies the one caveat labels alone can't express: a
+// namespace-level webhook match doesn't guarantee injection if the webhook's
+// objectSelector excludes this particular pod. Both Analyze's pod loop and
+// Evaluate call this, so the two can never disagree about a given
+// namespace+pod.
+func evaluatePodOverride(d namespaceDecision, nsLabels, podLabels, podAnnotations map[string]string) injectionOverride {
+	override := resolveInjectionOverride(d.injected, nsLabels, podLabels, podAnnotations)
+	if override.inject && d.webhook != nil && !d.byLegacyLabel && !d.webhook.matchesPod(podLabels) {
+		override.inject = false
+	}
+	return override
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injection
+
+import (
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/resource"
+)
+
+// deactivatedSelectorLabel is the label key the Istio installer writes into a
+// webhook's namespaceSelector/objectSelector when the webhook should be
+// treated as permanently disabled (e.g. while migrating revisions with
+// `istioctl x revision tag`). Nothing else in the cluster ever sets this
+// label, so a selector that requires it can never match a real object.
+const deactivatedSelectorLabel = "istio.io/deactivated"
+
+// revisionTagLabel is set on a MutatingWebhookConfiguration created by
+// `istioctl tag set` to mark it as a stable alias (e.g. "default" or
+// "prod") for the control plane revision it points at.
+const revisionTagLabel = "istio.io/tag"
+
+// revisionTag, if this MutatingWebhookConfiguration carries the
+// istio.io/tag label, returns the tag name and the revision it resolves
+// to.
+func revisionTag(r *resource.Instance) (tag string, revision string, ok bool) {
+	tag, ok = r.Metadata.Labels[revisionTagLabel]
+	if !ok {
+		return "", "", false
+	}
+	revision = r.Metadata.Labels[model.RevisionLabel]
+	return tag, revision, true
+}
+
+// sidecarInjectorWebhook is a single MutatingWebhook entry from a
+// MutatingWebhookConfiguration, resolved down to the fields the injection
+// analyzer cares about.
+//
+// reinvocationPolicy is deliberately not carried here: it only changes
+// whether the webhook reruns after a later mutating webhook edits the pod
+// spec, which leaves no trace this analyzer (working from already-admitted
+// pod objects) could ever distinguish from any other cause of a missing or
+// altered proxy. failurePolicy, by contrast, does: see its doc comment.
+type sidecarInjectorWebhook struct {
+	configName        string
+	name              string
+	revision          string
+	namespaceSelector labels.Selector
+	objectSelector    labels.Selector
+	// failurePolicy is Ignore or Fail, resolved from the webhook's own
+	// setting. When a matched webhook's pod is missing its proxy, an Ignore
+	// policy means admission would have proceeded even if the webhook call
+	// itself failed, so the missing proxy is more likely a silently-ignored
+	// webhook failure than deliberate exclusion -- worth its own diagnostic.
+	failurePolicy admissionregistrationv1.FailurePolicyType
+	deactivated   bool
+}
+
+// isSidecarInjectorWebhookConfig reports whether the given
+// MutatingWebhookConfiguration looks like one of Istio's sidecar injectors,
+// as opposed to some unrelated webhook the cluster operator installed.
+func isSidecarInjectorWebhookConfig(name string, webhookLabels map[string]string) bool {
+	if strings.Contains(name, "sidecar-injector") {
+		return true
+	}
+	if _, ok := webhookLabels[model.RevisionLabel]; ok {
+		return true
+	}
+	return false
+}
+
+func selectorIsDeactivated(sel *metav1.LabelSelector) bool {
+	if sel == nil {
+		return false
+	}
+	for _, req := range sel.MatchExpressions {
+		if req.Key == deactivatedSelectorLabel {
+			return true
+		}
+	}
+	return false
+}
+
+func toSelector(sel *metav1.LabelSelector) labels.Selector {
+	if sel == nil {
+		return labels.Everything()
+	}
+	s, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		// An invalid selector can never match anything.
+		return labels.Nothing()
+	}
+	return s
+}
+
+func failurePolicyOrDefault(p *admissionregistrationv1.FailurePolicyType) admissionregistrationv1.FailurePolicyType {
+	if p == nil {
+		return admissionregistrationv1.Ignore
+	}
+	return *p
+}
+
+// extractSidecarInjectorWebhooks flattens the individual webhook entries out
+// of a MutatingWebhookConfiguration resource, keeping only the ones that
+// look like Istio sidecar injectors.
+func extractSidecarInjectorWebhooks(r *resource.Instance) []sidecarInjectorWebhook {
+	cfg := r.Message.(*admissionregistrationv1.MutatingWebhookConfiguration)
+	if !isSidecarInjectorWebhookConfig(cfg.GetName(), r.Metadata.Labels) {
+		return nil
+	}
+
+	revision := r.Metadata.Labels[model.RevisionLabel]
+
+	out := make([]sidecarInjectorWebhook, 0, len(cfg.Webhooks))
+	for _, wh := range cfg.Webhooks {
+		out = append(out, sidecarInjectorWebhook{
+			configName:        cfg.GetName(),
+			name:              wh.Name,
+			revision:          revision,
+			namespaceSelector: toSelector(wh.NamespaceSelector),
+			objectSelector:    toSelector(wh.ObjectSelector),
+			failurePolicy:     failurePolicyOrDefault(wh.FailurePolicy),
+			deactivated:       selectorIsDeactivated(wh.NamespaceSelector) || selectorIsDeactivated(wh.ObjectSelector),
+		})
+	}
+	return out
+}
+
+// matchingWebhooks returns the active (non-deactivated) webhooks whose
+// namespaceSelector matches nsLabels.
+func matchingWebhooks(webhooks []sidecarInjectorWebhook, nsLabels map[string]string) []sidecarInjectorWebhook {
+	set := labels.Set(nsLabels)
+	var out []sidecarInjectorWebhook
+	for _, wh := range webhooks {
+		if wh.deactivated {
+			continue
+		}
+		if wh.namespaceSelector.Matches(set) {
+			out = append(out, wh)
+		}
+	}
+	return out
+}
+
+// matchesPod reports whether the pod's labels satisfy this webhook's
+// objectSelector.
+func (w sidecarInjectorWebhook) matchesPod(podLabels map[string]string) bool {
+	return w.objectSelector.Matches(labels.Set(podLabels))
+}
+
+// distinctRevisions returns the set of distinct revisions represented among
+// the given webhooks.
+func distinctRevisions(webhooks []sidecarInjectorWebhook) []string {
+	seen := make(map[string]bool, len(webhooks))
+	var out []string
+	for _, wh := range webhooks {
+		if seen[wh.revision] {
+			continue
+		}
+		seen[wh.revision] = true
+		out = append(out, wh.revision)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// GENERATED FILE -- DO NOT EDIT
+//
+// Generated from messages.yaml
+
+package msg
+
+import (
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/resource"
+)
+
+var (
+	// InjectionWebhookDeactivated defines a diag.MessageType for message "InjectionWebhookDeactivated".
+	InjectionWebhookDeactivated = diag.NewMessageType(diag.Info, "IST0161", "The sidecar-injector webhook %q is deactivated (its selector requires istio.io/deactivated) and will never inject")
+
+	// NamespaceInjectionWebhookConflict defines a diag.MessageType for message "NamespaceInjectionWebhookConflict".
+	NamespaceInjectionWebhookConflict = diag.NewMessageType(diag.Warning, "IST0162", "Namespace %q matches sidecar-injector webhooks from multiple revisions (%s); injection behavior is ambiguous")
+
+	// PodMissingProxyViaWebhook defines a diag.MessageType for message "PodMissingProxyViaWebhook".
+	PodMissingProxyViaWebhook = diag.NewMessageType(diag.Warning, "IST0163", "The pod is missing its Istio proxy, though its namespace is injected via the %q sidecar-injector webhook")
+
+	// NamespaceInjectionTagDangling defines a diag.MessageType for message "NamespaceInjectionTagDangling".
+	NamespaceInjectionTagDangling = diag.NewMessageType(diag.Warning, "IST0164", "The istio.io/rev label %q resolves to revision %q, which has no running control-plane pods (namespace %q)")
+
+	// ConflictingInjectionDirectives defines a diag.MessageType for message "ConflictingInjectionDirectives".
+	ConflictingInjectionDirectives = diag.NewMessageType(diag.Warning, "IST0165", "The pod's sidecar.istio.io/inject directives conflict across scopes; injection behavior may not match any single directive")
+
+	// PodStaleSidecarInjection defines a diag.MessageType for message "PodStaleSidecarInjection".
+	PodStaleSidecarInjection = diag.NewMessageType(diag.Warning, "IST0166", "This pod's sidecar placement doesn't match the rest of revision %q's injected pods; it may predate or postdate a native-sidecar migration and should be redeployed")
+
+	// NamespaceAmbientAndSidecarInjectionConflict defines a diag.MessageType for message "NamespaceAmbientAndSidecarInjectionConflict".
+	NamespaceAmbientAndSidecarInjectionConflict = diag.NewMessageType(diag.Warning, "IST0167", "Namespace %q is in ambient mode but also labeled for sidecar injection; ambient mode takes precedence and no sidecar will be injected")
+
+	// PodWantsSidecarInAmbientNamespace defines a diag.MessageType for message "PodWantsSidecarInAmbientNamespace".
+	PodWantsSidecarInAmbientNamespace = diag.NewMessageType(diag.Warning, "IST0168", "This pod requests sidecar injection, but its namespace is in ambient mode and will capture it with ztunnel instead of injecting a sidecar")
+
+	// PodMissingProxyWebhookIgnoreFailurePolicy defines a diag.MessageType for message "PodMissingProxyWebhookIgnoreFailurePolicy".
+	PodMissingProxyWebhookIgnoreFailurePolicy = diag.NewMessageType(diag.Error, "IST0169", "The pod is missing its Istio proxy from the %q webhook, whose failurePolicy is Ignore: admission likely proceeded despite a webhook failure rather than failing closed")
+)
+
+// NewInjectionWebhookDeactivated returns a new diag.Message based on InjectionWebhookDeactivated.
+func NewInjectionWebhookDeactivated(r *resource.Instance, webhookName string) diag.Message {
+	return diag.NewMessage(
+		InjectionWebhookDeactivated,
+		r,
+		webhookName,
+	)
+}
+
+// NewNamespaceInjectionWebhookConflict returns a new diag.Message based on NamespaceInjectionWebhookConflict.
+func NewNamespaceInjectionWebhookConflict(r *resource.Instance, namespace string, revisions string) diag.Message {
+	return diag.NewMessage(
+		NamespaceInjectionWebhookConflict,
+		r,
+		namespace,
+		revisions,
+	)
+}
+
+// NewPodMissingProxyViaWebhook returns a new diag.Message based on PodMissingProxyViaWebhook.
+func NewPodMissingProxyViaWebhook(r *resource.Instance, webhookName string) diag.Message {
+	return diag.NewMessage(
+		PodMissingProxyViaWebhook,
+		r,
+		webhookName,
+	)
+}
+
+// NewNamespaceInjectionTagDangling returns a new diag.Message based on NamespaceInjectionTagDangling.
+func NewNamespaceInjectionTagDangling(r *resource.Instance, tag string, revision string, namespace string) diag.Message {
+	return diag.NewMessage(
+		NamespaceInjectionTagDangling,
+		r,
+		tag,
+		revision,
+		namespace,
+	)
+}
+
+// NewConflictingInjectionDirectives returns a new diag.Message based on ConflictingInjectionDirectives.
+func NewConflictingInjectionDirectives(r *resource.Instance) diag.Message {
+	return diag.NewMessage(
+		ConflictingInjectionDirectives,
+		r,
+	)
+}
+
+// NewPodStaleSidecarInjection returns a new diag.Message based on PodStaleSidecarInjection.
+func NewPodStaleSidecarInjection(r *resource.Instance, revision string) diag.Message {
+	return diag.NewMessage(
+		PodStaleSidecarInjection,
+		r,
+		revision,
+	)
+}
+
+// NewNamespaceAmbientAndSidecarInjectionConflict returns a new diag.Message based on NamespaceAmbientAndSidecarInjectionConflict.
+func NewNamespaceAmbientAndSidecarInjectionConflict(r *resource.Instance, namespace string) diag.Message {
+	return diag.NewMessage(
+		NamespaceAmbientAndSidecarInjectionConflict,
+		r,
+		namespace,
+	)
+}
+
+// NewPodWantsSidecarInAmbientNamespace returns a new diag.Message based on PodWantsSidecarInAmbientNamespace.
+func NewPodWantsSidecarInAmbientNamespace(r *resource.Instance) diag.Message {
+	return diag.NewMessage(
+		PodWantsSidecarInAmbientNamespace,
+		r,
+	)
+}
+
+// NewPodMissingProxyWebhookIgnoreFailurePolicy returns a new diag.Message based on PodMissingProxyWebhookIgnoreFailurePolicy.
+func NewPodMissingProxyWebhookIgnoreFailurePolicy(r *resource.Instance, webhookName string) diag.Message {
+	return diag.NewMessage(
+		PodMissingProxyWebhookIgnoreFailurePolicy,
+		r,
+		webhookName,
+	)
+}